@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/jobs"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// JobServerCommand runs only the amboy worker loop against the
+// application database, with none of the HTTP API or UI. It lets heavy
+// jobs (like historical stats caching) scale on their own hosts,
+// independently of the API server that enqueues them.
+type JobServerCommand struct {
+	ConfigPath string `long:"conf" default:"/etc/mci_settings.yml" description:"path to the service configuration file"`
+	MongoDBURI string `long:"mongodburi" default:"" description:"alternate mongodb uri, override config file"`
+}
+
+func (c *JobServerCommand) Execute(_ []string) error {
+	settings, err := evergreen.NewSettings(c.ConfigPath)
+	if err != nil {
+		return errors.Wrap(err, "problem getting settings")
+	}
+
+	if err = settings.Validate(); err != nil {
+		return errors.Wrap(err, "problem validating settings")
+	}
+
+	if c.MongoDBURI == "" {
+		c.MongoDBURI = settings.Database.Url
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	queue, err := evergreen.GetEnvironment().RemoteQueue()
+	if err != nil {
+		return errors.Wrap(err, "problem getting remote queue")
+	}
+
+	if err = queue.Start(ctx); err != nil {
+		return errors.Wrap(err, "problem starting queue")
+	}
+
+	grip.Info("jobserver running, registered job types: " + describeJobTypes())
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	<-sigChan
+
+	// Cancelling ctx only stops the queue from picking up new work;
+	// draining in-flight jobs needs its own, uncancelled context, or
+	// Wait would return the instant ctx is done instead of waiting for
+	// those jobs to finish.
+	cancel()
+	queue.Wait(context.Background())
+
+	return nil
+}
+
+func describeJobTypes() string {
+	types := jobs.DefaultScheduler.JobTypes()
+	out := ""
+	for i, jobType := range types {
+		if i > 0 {
+			out += ", "
+		}
+		out += jobType
+	}
+
+	return out
+}
@@ -0,0 +1,91 @@
+// Package jobs generalizes evergreen's amboy usage into a first-class
+// subsystem: a registry of job types to worker implementations, a
+// persisted JobStatus per run, and (via cli.JobServerCommand) a binary mode
+// that runs only the worker loop so heavy jobs can scale independently
+// of the API server.
+package jobs
+
+import (
+	"context"
+
+	"github.com/evergreen-ci/evergreen/jobstatus"
+	"github.com/mongodb/amboy"
+	"github.com/pkg/errors"
+)
+
+// Worker knows how to build the amboy.Job for one job type.
+type Worker interface {
+	// JobType is the unique, stable name this worker is registered
+	// under (e.g. "cache-historical-test-data", "manifest-load").
+	JobType() string
+	// NewJob builds the amboy.Job to enqueue for id.
+	NewJob(id string) amboy.Job
+}
+
+// Scheduler dispatches job types to the Worker that knows how to build
+// and run them.
+type Scheduler struct {
+	workers map[string]Worker
+}
+
+// NewScheduler returns an empty Scheduler; call Register to populate it.
+func NewScheduler() *Scheduler {
+	return &Scheduler{workers: map[string]Worker{}}
+}
+
+// Register associates a Worker with its JobType. Re-registering the
+// same job type replaces the previous worker, so callers can override
+// the default registry for tests.
+func (s *Scheduler) Register(worker Worker) {
+	s.workers[worker.JobType()] = worker
+}
+
+// Get returns the Worker registered for jobType.
+func (s *Scheduler) Get(jobType string) (Worker, error) {
+	worker, ok := s.workers[jobType]
+	if !ok {
+		return nil, errors.Errorf("no worker registered for job type '%s'", jobType)
+	}
+
+	return worker, nil
+}
+
+// JobTypes returns every job type currently registered, in no particular
+// order.
+func (s *Scheduler) JobTypes() []string {
+	types := make([]string, 0, len(s.workers))
+	for jobType := range s.workers {
+		types = append(types, jobType)
+	}
+
+	return types
+}
+
+// Enqueue builds the job for jobType/id via its registered Worker,
+// records a JobStatus for it (keeping id as JobStatus.RawID so the same
+// run can be retriggered later), and puts it on queue.
+func (s *Scheduler) Enqueue(ctx context.Context, queue amboy.Queue, jobType string, id string) (*jobstatus.JobStatus, error) {
+	worker, err := s.Get(jobType)
+	if err != nil {
+		return nil, err
+	}
+
+	job := worker.NewJob(id)
+
+	status := jobstatus.NewJobStatus(job.ID(), jobType, id)
+	if err := status.Upsert(); err != nil {
+		return nil, errors.Wrap(err, "problem recording job status")
+	}
+
+	if err := queue.Put(job); err != nil {
+		status.MarkError(err)
+		_ = status.Upsert()
+		return nil, errors.Wrapf(err, "problem enqueueing job %s", job.ID())
+	}
+
+	return status, nil
+}
+
+// DefaultScheduler is the process-wide registry. Individual worker
+// implementations register themselves into it from their own init().
+var DefaultScheduler = NewScheduler()
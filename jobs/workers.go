@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/units"
+	"github.com/mongodb/amboy"
+)
+
+// cacheHistoricalTestDataWorker adapts units.NewCacheHistoricalTestDataJob
+// to the Worker interface so it can be scheduled/canceled/retriggered
+// through the generic jobs subsystem.
+type cacheHistoricalTestDataWorker struct{}
+
+func (w *cacheHistoricalTestDataWorker) JobType() string { return "cache-historical-test-data" }
+
+// NewJob treats id as the project id to sync. The job's own id also
+// carries the current time, so each call (including a retrigger of an
+// earlier run for the same project) yields a distinct job id.
+func (w *cacheHistoricalTestDataWorker) NewJob(id string) amboy.Job {
+	return units.NewCacheHistoricalTestDataJob(id, time.Now().UTC().Format(time.RFC3339Nano))
+}
+
+// periodicProjectTriggerWorker adapts units.NewPeriodicProjectTriggerJob
+// to the Worker interface.
+type periodicProjectTriggerWorker struct{}
+
+func (w *periodicProjectTriggerWorker) JobType() string { return "periodic-project-trigger" }
+
+// NewJob appends the current time to id, so each call yields a distinct
+// job id.
+func (w *periodicProjectTriggerWorker) NewJob(id string) amboy.Job {
+	return units.NewPeriodicProjectTriggerJob(id + "." + time.Now().UTC().Format(time.RFC3339Nano))
+}
+
+func init() {
+	DefaultScheduler.Register(&cacheHistoricalTestDataWorker{})
+	DefaultScheduler.Register(&periodicProjectTriggerWorker{})
+}
@@ -0,0 +1,136 @@
+// Package jobstatus holds the persisted JobStatus record and is kept
+// separate from package jobs so that units (which jobs/workers.go
+// depends on to build amboy jobs) can report its own status without
+// creating an import cycle back through jobs.
+package jobstatus
+
+import (
+	"sort"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const jobStatusCollection = "jobs.status"
+
+// Status values a JobStatus can hold across its lifetime.
+const (
+	StatusPending    = "pending"
+	StatusInProgress = "in-progress"
+	StatusSuccess    = "success"
+	StatusError      = "error"
+	StatusCanceled   = "canceled"
+)
+
+// JobStatus is the persisted record of one job run, independent of
+// amboy's own in-memory job state, so `/rest/v2/jobs` can report on runs
+// even after the worker process that ran them has recycled.
+type JobStatus struct {
+	Id      string `bson:"_id" json:"id"`
+	JobType string `bson:"job_type" json:"job_type"`
+	// RawID is the argument that was originally passed to
+	// Worker.NewJob to produce this job, e.g. a project id. It's kept
+	// around so a job can be retriggered by replaying the same
+	// arguments rather than by guessing them back out of Id, which is
+	// the job's own composite id and not necessarily parseable the same
+	// way by every worker.
+	RawID        string    `bson:"raw_id" json:"raw_id"`
+	Status       string    `bson:"status" json:"status"`
+	ProgressPct  float64   `bson:"progress_pct" json:"progress_pct"`
+	LastActivity time.Time `bson:"last_activity" json:"last_activity"`
+	NextRun      time.Time `bson:"next_run,omitempty" json:"next_run,omitempty"`
+	Error        string    `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+// NewJobStatus returns a pending JobStatus for id/jobType/rawID.
+func NewJobStatus(id string, jobType string, rawID string) *JobStatus {
+	return &JobStatus{
+		Id:           id,
+		JobType:      jobType,
+		RawID:        rawID,
+		Status:       StatusPending,
+		LastActivity: time.Now(),
+	}
+}
+
+// MarkInProgress transitions the status to in-progress and records the
+// given completion percentage (0-100).
+func (s *JobStatus) MarkInProgress(progressPct float64) {
+	s.Status = StatusInProgress
+	s.ProgressPct = progressPct
+	s.LastActivity = time.Now()
+}
+
+// MarkSuccess transitions the status to success with progress at 100%.
+func (s *JobStatus) MarkSuccess() {
+	s.Status = StatusSuccess
+	s.ProgressPct = 100
+	s.LastActivity = time.Now()
+}
+
+// MarkError transitions the status to error and records err's message.
+func (s *JobStatus) MarkError(err error) {
+	s.Status = StatusError
+	s.Error = err.Error()
+	s.LastActivity = time.Now()
+}
+
+// MarkCanceled transitions the status to canceled.
+func (s *JobStatus) MarkCanceled() {
+	s.Status = StatusCanceled
+	s.LastActivity = time.Now()
+}
+
+// Upsert persists s, overwriting any existing status for the same job
+// id.
+func (s *JobStatus) Upsert() error {
+	_, err := db.Upsert(jobStatusCollection, bson.M{"_id": s.Id}, s)
+	return errors.Wrap(err, "problem upserting job status")
+}
+
+// FindJobStatus returns the persisted status for id, or nil if none
+// exists.
+func FindJobStatus(id string) (*JobStatus, error) {
+	status := &JobStatus{}
+	err := db.FindOneQ(jobStatusCollection, bson.M{"_id": id}, status)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding job status")
+	}
+
+	return status, nil
+}
+
+// FindJobStatusesByType returns every persisted status for jobType, most
+// recently active first.
+func FindJobStatusesByType(jobType string) ([]JobStatus, error) {
+	statuses := []JobStatus{}
+	err := db.FindAllQ(jobStatusCollection, bson.M{"job_type": jobType}, &statuses)
+	if err != nil {
+		return statuses, errors.Wrap(err, "problem finding job statuses")
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].LastActivity.After(statuses[j].LastActivity)
+	})
+
+	return statuses, nil
+}
+
+// IsCanceled reports whether id's persisted status has been flagged
+// canceled (e.g. via the REST cancel endpoint), re-reading from the
+// database each call so a long-running job can poll it mid-run instead
+// of only ever seeing the status it started with.
+func IsCanceled(id string) (bool, error) {
+	status, err := FindJobStatus(id)
+	if err != nil {
+		return false, err
+	}
+
+	return status != nil && status.Status == StatusCanceled, nil
+}
@@ -0,0 +1,30 @@
+package manifest
+
+import (
+	"context"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/pkg/errors"
+)
+
+// BitbucketResolver resolves module revisions for modules hosted on
+// Bitbucket.
+type BitbucketResolver struct{}
+
+func (r *BitbucketResolver) Resolve(ctx context.Context, settings ResolverSettings, module model.Module) (*Module, error) {
+	owner, repo := module.GetRepoOwnerAndName()
+
+	commit, err := thirdparty.GetBitbucketBranchHead(ctx, owner, repo, module.Branch, settings.BitbucketToken)
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem getting bitbucket branch for module %s", module.Name)
+	}
+
+	return &Module{
+		Branch:   module.Branch,
+		Revision: commit.SHA,
+		Repo:     repo,
+		Owner:    owner,
+		URL:      commit.URL,
+	}, nil
+}
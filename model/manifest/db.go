@@ -0,0 +1,34 @@
+package manifest
+
+import (
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// FindOne returns a single manifest that satisfies the given query.
+func FindOne(query bson.M) (*Manifest, error) {
+	m := &Manifest{}
+	err := db.FindOneQ(Collection, query, m)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding manifest")
+	}
+
+	return m, nil
+}
+
+// TryInsert inserts the manifest and reports whether it was a duplicate
+// of an existing manifest for the same version, rather than treating
+// that as an error.
+func (m *Manifest) TryInsert() (bool, error) {
+	err := db.Insert(Collection, m)
+	if mgo.IsDup(err) {
+		return true, nil
+	}
+
+	return false, errors.Wrap(err, "problem inserting manifest")
+}
@@ -0,0 +1,71 @@
+package manifest
+
+import (
+	"context"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+)
+
+// ModuleDiff describes what resolving a single module would change
+// relative to any existing manifest, without writing anything.
+type ModuleDiff struct {
+	Name        string `json:"name"`
+	OldRevision string `json:"old_revision,omitempty"`
+	NewRevision string `json:"new_revision"`
+	Author      string `json:"author,omitempty"`
+	Date        string `json:"date,omitempty"`
+}
+
+// DryRunResult is what manifestLoadHandler returns in dry-run mode: the
+// per-module diff against any manifest that already exists for the
+// version, with none of it written.
+type DryRunResult struct {
+	VersionId string       `json:"version_id"`
+	Modules   []ModuleDiff `json:"modules"`
+}
+
+// BuildDryRun resolves the head revision of every module the same way a
+// real load would, but only computes the diff against existing's
+// modules rather than calling TryInsert. Commit author/date is
+// best-effort: it's only available for modules resolved via GitHub,
+// since that's the only provider thirdparty currently exposes commit
+// metadata for.
+func BuildDryRun(ctx context.Context, settings ResolverSettings, versionId string, project *model.Project, existing *Manifest) (*DryRunResult, error) {
+	result := &DryRunResult{VersionId: versionId}
+
+	for _, module := range project.Modules {
+		resolver, err := GetResolver(module.Provider)
+		if err != nil {
+			return nil, err
+		}
+
+		resolved, err := resolver.Resolve(ctx, settings, module)
+		if err != nil {
+			return nil, err
+		}
+
+		diff := ModuleDiff{
+			Name:        module.Name,
+			NewRevision: resolved.Revision,
+		}
+		if existing != nil {
+			if old, ok := existing.Modules[module.Name]; ok {
+				diff.OldRevision = old.Revision
+			}
+		}
+
+		if module.Provider == "" || module.Provider == model.ModuleProviderGithub {
+			owner, repo := module.GetRepoOwnerAndName()
+			commit, err := thirdparty.GetCommitMetadata(ctx, settings.GithubToken, owner, repo, resolved.Revision)
+			if err == nil && commit != nil {
+				diff.Author = commit.Author
+				diff.Date = commit.Date
+			}
+		}
+
+		result.Modules = append(result.Modules, diff)
+	}
+
+	return result, nil
+}
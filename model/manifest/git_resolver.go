@@ -0,0 +1,34 @@
+package manifest
+
+import (
+	"context"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/pkg/errors"
+)
+
+// GitResolver is the fallback resolver for modules hosted on an SCM we
+// don't have a dedicated API integration for. It shells out to
+// `git ls-remote` against the module's configured CloneURL, so it needs
+// no credentials but also can't report commit metadata beyond the SHA.
+// Unlike the other resolvers, a generic git remote has no "owner/repo"
+// to report: Owner/Repo are left blank in the returned Module.
+type GitResolver struct{}
+
+func (r *GitResolver) Resolve(ctx context.Context, settings ResolverSettings, module model.Module) (*Module, error) {
+	if module.CloneURL == "" {
+		return nil, errors.Errorf("module %s uses provider '%s' but has no clone_url configured", module.Name, model.ModuleProviderGit)
+	}
+
+	revision, err := thirdparty.GetGenericGitBranchHead(ctx, module.CloneURL, module.Branch)
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem running git ls-remote for module %s", module.Name)
+	}
+
+	return &Module{
+		Branch:   module.Branch,
+		Revision: revision,
+		URL:      module.CloneURL,
+	}, nil
+}
@@ -0,0 +1,30 @@
+package manifest
+
+import (
+	"context"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/pkg/errors"
+)
+
+// GithubResolver resolves module revisions by calling the GitHub API for
+// the head commit of the module's branch.
+type GithubResolver struct{}
+
+func (r *GithubResolver) Resolve(ctx context.Context, settings ResolverSettings, module model.Module) (*Module, error) {
+	owner, repo := module.GetRepoOwnerAndName()
+
+	gitBranch, err := thirdparty.GetBranchEvent(ctx, settings.GithubToken, owner, repo, module.Branch)
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem getting git branch for module %s", module.Name)
+	}
+
+	return &Module{
+		Branch:   module.Branch,
+		Revision: *gitBranch.Commit.SHA,
+		Repo:     repo,
+		Owner:    owner,
+		URL:      *gitBranch.Commit.URL,
+	}, nil
+}
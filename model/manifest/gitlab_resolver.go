@@ -0,0 +1,29 @@
+package manifest
+
+import (
+	"context"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/pkg/errors"
+)
+
+// GitlabResolver resolves module revisions for modules hosted on GitLab.
+type GitlabResolver struct{}
+
+func (r *GitlabResolver) Resolve(ctx context.Context, settings ResolverSettings, module model.Module) (*Module, error) {
+	owner, repo := module.GetRepoOwnerAndName()
+
+	commit, err := thirdparty.GetGitlabBranchHead(ctx, owner, repo, module.Branch, settings.GitlabToken)
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem getting gitlab branch for module %s", module.Name)
+	}
+
+	return &Module{
+		Branch:   module.Branch,
+		Revision: commit.SHA,
+		Repo:     repo,
+		Owner:    owner,
+		URL:      commit.URL,
+	}, nil
+}
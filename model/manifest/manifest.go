@@ -0,0 +1,33 @@
+package manifest
+
+import (
+	"gopkg.in/mgo.v2/bson"
+)
+
+const Collection = "manifest"
+
+// Manifest records the exact revisions used for a version's modules so
+// that a version can be reproduced later even if the module branches
+// have since moved on.
+type Manifest struct {
+	Id          string             `bson:"_id" json:"id"`
+	Revision    string             `bson:"revision" json:"revision"`
+	ProjectName string             `bson:"project" json:"project"`
+	Branch      string             `bson:"branch" json:"branch"`
+	Modules     map[string]*Module `bson:"modules" json:"modules"`
+}
+
+// Module is the manifest's record of a single module's resolved
+// revision at the time the version was created.
+type Module struct {
+	Branch   string `bson:"branch" json:"branch"`
+	Revision string `bson:"revision" json:"revision"`
+	Repo     string `bson:"repo" json:"repo"`
+	Owner    string `bson:"owner" json:"owner"`
+	URL      string `bson:"url" json:"url"`
+}
+
+// ById returns the query for a manifest with the given version id.
+func ById(id string) bson.M {
+	return bson.M{"_id": id}
+}
@@ -0,0 +1,59 @@
+package manifest
+
+import (
+	"context"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/pkg/errors"
+)
+
+// ModuleResolver fetches the head revision for a project module from
+// whichever SCM hosts it, and translates the result into a manifest
+// Module entry.
+type ModuleResolver interface {
+	// Resolve looks up the current head of module's branch and returns
+	// the manifest entry that should be recorded for it.
+	Resolve(ctx context.Context, settings ResolverSettings, module model.Module) (*Module, error)
+}
+
+// ResolverSettings bundles the credentials a resolver may need to talk to
+// its SCM. Not every resolver uses every field; GitResolver uses none,
+// since it authenticates however the host running the job's git client
+// is already configured to.
+type ResolverSettings struct {
+	GithubToken    string
+	GitlabToken    string
+	BitbucketToken string
+}
+
+var resolverRegistry = map[string]ModuleResolver{}
+
+// RegisterResolver associates a module provider (model.ModuleProviderGithub,
+// etc.) with the resolver that knows how to fetch revisions for it.
+func RegisterResolver(provider string, resolver ModuleResolver) {
+	resolverRegistry[provider] = resolver
+}
+
+// GetResolver returns the resolver registered for the given module
+// provider. A module that does not declare a provider (e.g. one from a
+// project created before this field existed) gets the GitHub resolver,
+// for backwards compatibility.
+func GetResolver(provider string) (ModuleResolver, error) {
+	if provider == "" {
+		provider = model.ModuleProviderGithub
+	}
+
+	resolver, ok := resolverRegistry[provider]
+	if !ok {
+		return nil, errors.Errorf("no manifest module resolver registered for provider '%s'", provider)
+	}
+
+	return resolver, nil
+}
+
+func init() {
+	RegisterResolver(model.ModuleProviderGithub, &GithubResolver{})
+	RegisterResolver(model.ModuleProviderGitlab, &GitlabResolver{})
+	RegisterResolver(model.ModuleProviderBitbucket, &BitbucketResolver{})
+	RegisterResolver(model.ModuleProviderGit, &GitResolver{})
+}
@@ -0,0 +1,50 @@
+package model
+
+import "strings"
+
+// Module provider types supported when resolving a module's manifest
+// entry. Projects created before this field existed are treated as
+// ModuleProviderGithub.
+const (
+	ModuleProviderGithub    = "github"
+	ModuleProviderGitlab    = "gitlab"
+	ModuleProviderBitbucket = "bitbucket"
+	ModuleProviderGit       = "git"
+)
+
+// Module represents a second repository that is checked out alongside a
+// project's main repository at a task's working directory.
+type Module struct {
+	Name string `yaml:"name" bson:"name"`
+	// Branch is the module's branch to check out. Repo's meaning
+	// depends on Provider: for ModuleProviderGithub/Gitlab/Bitbucket
+	// it's an "owner/repo" slug looked up through that host's API (see
+	// GetRepoOwnerAndName); for ModuleProviderGit it's ignored in favor
+	// of CloneURL, since a generic git remote has no owner/repo
+	// concept.
+	Branch string `yaml:"branch" bson:"branch"`
+	Repo   string `yaml:"repo" bson:"repo"`
+	Prefix string `yaml:"prefix" bson:"prefix"`
+
+	// CloneURL is the full URL `git ls-remote` uses to look up this
+	// module's branch head when Provider is ModuleProviderGit. Ignored
+	// by every other provider, which resolve Repo through their host's
+	// API instead.
+	CloneURL string `yaml:"clone_url" bson:"clone_url"`
+
+	// Provider indicates which SCM hosts this module's repo, so that
+	// manifest resolution knows which API to call. Defaults to GitHub
+	// when unset.
+	Provider string `yaml:"provider" bson:"provider"`
+}
+
+// GetRepoOwnerAndName splits a module's configured "owner/repo" Repo
+// field into its two parts.
+func (m *Module) GetRepoOwnerAndName() (string, string) {
+	parts := strings.Split(m.Repo, "/")
+	if len(parts) != 2 {
+		return "", ""
+	}
+
+	return parts[0], parts[1]
+}
@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+// Periodic trigger types a task or build variant can declare via the
+// `trigger` field in the project YAML. These control whether/when the
+// scheduler should automatically create a version for that variant
+// outside of the normal commit-triggered flow.
+const (
+	// TriggerNightly runs once every 24 hours.
+	TriggerNightly = "nightly"
+	// TriggerWeekly runs once every 7 days.
+	TriggerWeekly = "weekly"
+	// TriggerOnDemand never runs automatically; it's only available to
+	// force/try invocations.
+	TriggerOnDemand = "on-demand"
+	// TriggerAnyBranch runs nightly, including on branches other than
+	// the project's default branch.
+	TriggerAnyBranch = "any-branch"
+	// TriggerMasterOnly runs nightly, but only on the project's default
+	// branch; it's suppressed on every other branch even if the task
+	// defining it is reachable there.
+	TriggerMasterOnly = "master-only"
+)
+
+// periodicTriggerIntervals maps a trigger type to how often it's due to
+// run. TriggerOnDemand isn't in this map: it never runs on a cadence of
+// its own, only via force/try invocations.
+var periodicTriggerIntervals = map[string]time.Duration{
+	TriggerNightly:    24 * time.Hour,
+	TriggerWeekly:     7 * 24 * time.Hour,
+	TriggerAnyBranch:  24 * time.Hour,
+	TriggerMasterOnly: 24 * time.Hour,
+}
+
+// IsDue reports whether a trigger of type triggerType is due to run
+// again, given it last ran at lastRun (the zero Time if it has never
+// run). TriggerOnDemand is never due automatically.
+func IsDue(triggerType string, lastRun time.Time, now time.Time) bool {
+	interval, ok := periodicTriggerIntervals[triggerType]
+	if !ok {
+		return false
+	}
+
+	return lastRun.IsZero() || now.Sub(lastRun) >= interval
+}
+
+// defaultBranchNames lists the branch names evergreen treats as a
+// project's default branch for the purposes of TriggerMasterOnly.
+var defaultBranchNames = map[string]bool{
+	"master": true,
+	"main":   true,
+}
+
+// AppliesToBranch reports whether triggerType should fire for a project
+// tracking the given branch. Only TriggerMasterOnly is
+// branch-restricted; it is suppressed on every branch other than the
+// project's default branch, even when the task declaring it is
+// reachable there.
+func AppliesToBranch(triggerType string, branch string) bool {
+	return triggerType != TriggerMasterOnly || defaultBranchNames[branch]
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const periodicTriggerStatusCollection = "periodic_trigger_status"
+
+// PeriodicTriggerStatus records the last time a given project+trigger
+// combination ran, so the scheduler job can compute what's due without
+// re-deriving it from version history on every tick.
+type PeriodicTriggerStatus struct {
+	Id        string    `bson:"_id" json:"id"`
+	ProjectId string    `bson:"project_id" json:"project_id"`
+	Trigger   string    `bson:"trigger" json:"trigger"`
+	LastRun   time.Time `bson:"last_run" json:"last_run"`
+}
+
+func periodicTriggerStatusId(projectId, trigger string) string {
+	return projectId + "." + trigger
+}
+
+// FindPeriodicTriggerStatus returns the last-run record for a
+// project+trigger pair, or nil if the trigger has never run.
+func FindPeriodicTriggerStatus(projectId, trigger string) (*PeriodicTriggerStatus, error) {
+	status := &PeriodicTriggerStatus{}
+	err := db.FindOneQ(periodicTriggerStatusCollection,
+		bson.M{"_id": periodicTriggerStatusId(projectId, trigger)}, status)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding periodic trigger status")
+	}
+
+	return status, nil
+}
+
+// UpdatePeriodicTriggerStatus records that trigger ran for projectId at
+// runTime.
+func UpdatePeriodicTriggerStatus(projectId, trigger string, runTime time.Time) error {
+	_, err := db.Upsert(periodicTriggerStatusCollection,
+		bson.M{"_id": periodicTriggerStatusId(projectId, trigger)},
+		bson.M{"$set": bson.M{
+			"project_id": projectId,
+			"trigger":    trigger,
+			"last_run":   runTime,
+		}})
+
+	return errors.Wrap(err, "problem updating periodic trigger status")
+}
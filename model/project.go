@@ -0,0 +1,71 @@
+package model
+
+// Project is the parsed form of a project's YAML configuration.
+type Project struct {
+	Tasks         []ProjectTask  `yaml:"tasks" bson:"tasks"`
+	BuildVariants []BuildVariant `yaml:"buildvariants" bson:"buildvariants"`
+	Modules       []Module       `yaml:"modules" bson:"modules"`
+}
+
+// ProjectTask is a task definition declared in the project's top-level
+// `tasks` list.
+type ProjectTask struct {
+	Name string `yaml:"name" bson:"name"`
+
+	// Trigger declares this task's periodic schedule (nightly, weekly,
+	// on-demand, any-branch, master-only). A build variant that runs
+	// this task inherits it unless the variant's reference to the task
+	// overrides it; it takes precedence over the variant's own trigger.
+	Trigger string `yaml:"trigger" bson:"trigger"`
+}
+
+// BuildVariant is a single entry in the project's `buildvariants` list.
+type BuildVariant struct {
+	Name string `yaml:"name" bson:"name"`
+
+	// Trigger is the default periodic schedule for every task this
+	// variant runs, unless the task itself (via ProjectTask.Trigger) or
+	// the variant's reference to it (via BuildVariantTask.Trigger)
+	// declares its own.
+	Trigger string `yaml:"trigger" bson:"trigger"`
+
+	Tasks []BuildVariantTask `yaml:"tasks" bson:"tasks"`
+}
+
+// BuildVariantTask references a ProjectTask from within a BuildVariant,
+// optionally overriding its trigger for this variant only.
+type BuildVariantTask struct {
+	Name string `yaml:"name" bson:"name"`
+
+	// Trigger, if set, overrides both the referenced ProjectTask's
+	// trigger and the BuildVariant's trigger for this task on this
+	// variant.
+	Trigger string `yaml:"trigger" bson:"trigger"`
+}
+
+// EffectiveTrigger resolves the periodic trigger that applies to task
+// running as part of variant, in order of precedence: the
+// BuildVariantTask's own override, then the ProjectTask's trigger, then
+// the BuildVariant's trigger.
+func EffectiveTrigger(task *ProjectTask, bvTask BuildVariantTask, variant BuildVariant) string {
+	if bvTask.Trigger != "" {
+		return bvTask.Trigger
+	}
+	if task != nil && task.Trigger != "" {
+		return task.Trigger
+	}
+
+	return variant.Trigger
+}
+
+// FindProjectTask returns the ProjectTask with the given name, or nil if
+// the project doesn't declare one.
+func (p *Project) FindProjectTask(name string) *ProjectTask {
+	for i := range p.Tasks {
+		if p.Tasks[i].Name == name {
+			return &p.Tasks[i]
+		}
+	}
+
+	return nil
+}
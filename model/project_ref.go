@@ -0,0 +1,43 @@
+package model
+
+import (
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const projectRefCollection = "project_ref"
+
+// ProjectRef holds the configuration evergreen needs to track and
+// schedule work for a single branch of a project. Most projects have
+// exactly one ProjectRef, but multi-branch projects register one per
+// tracked branch.
+type ProjectRef struct {
+	Identifier            string   `bson:"identifier" json:"identifier"`
+	Branch                string   `bson:"branch" json:"branch"`
+	FilesIgnoredFromCache []string `bson:"files_ignored_from_cache" json:"files_ignored_from_cache"`
+	Tracked               bool     `bson:"tracked" json:"tracked"`
+}
+
+// FindOneProjectRef finds the project ref with the given identifier.
+func FindOneProjectRef(identifier string) (*ProjectRef, error) {
+	ref := &ProjectRef{}
+	err := db.FindOneQ(projectRefCollection, bson.M{"identifier": identifier}, ref)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding project ref")
+	}
+
+	return ref, nil
+}
+
+// FindAllTrackedProjectRefs returns every project ref that evergreen
+// actively schedules work for, i.e. has Tracked set.
+func FindAllTrackedProjectRefs() ([]ProjectRef, error) {
+	refs := []ProjectRef{}
+	err := db.FindAllQ(projectRefCollection, bson.M{"tracked": true}, &refs)
+	return refs, errors.Wrap(err, "problem finding tracked project refs")
+}
@@ -0,0 +1,55 @@
+package stats
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Backend types supported by StatsBackend. MongoBackendType is the
+// default: it writes into the existing hourly/daily collections in the
+// application's own database. ElasticsearchBackendType targets a
+// separate search cluster for teams that want to query historical stats
+// at a scale Mongo aggregations don't handle well.
+const (
+	MongoBackendType         = "mongo"
+	ElasticsearchBackendType = "elasticsearch"
+)
+
+// StatsBackend generates and stores hourly/daily test and task stats.
+// cacheHistoricalTestDataJob calls through this interface instead of the
+// package-level Generate* functions directly, so it can target Mongo,
+// Elasticsearch, or any other store that implements it.
+type StatsBackend interface {
+	GenerateHourlyTestStats(projectId string, requester string, hour time.Time, tasks []string, jobTime time.Time) error
+	GenerateDailyTestStatsFromHourly(projectId string, requester string, day time.Time, tasks []string, jobTime time.Time) error
+	GenerateDailyTaskStats(projectId string, requester string, day time.Time, tasks []string, jobTime time.Time) error
+}
+
+var backendRegistry = map[string]StatsBackend{
+	MongoBackendType: &MongoBackend{},
+}
+
+// RegisterBackend associates a backend type name with the StatsBackend
+// implementation that should handle it. Backends that need
+// configuration (e.g. ElasticsearchBackend's index prefix and client)
+// register themselves from evergreen's settings-loading code rather than
+// from an init() here.
+func RegisterBackend(backendType string, backend StatsBackend) {
+	backendRegistry[backendType] = backend
+}
+
+// GetBackend returns the registered backend for backendType, defaulting
+// to MongoBackendType when backendType is empty.
+func GetBackend(backendType string) (StatsBackend, error) {
+	if backendType == "" {
+		backendType = MongoBackendType
+	}
+
+	backend, ok := backendRegistry[backendType]
+	if !ok {
+		return nil, errors.Errorf("no stats backend registered for type '%s'", backendType)
+	}
+
+	return backend, nil
+}
@@ -0,0 +1,65 @@
+package stats
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const checkpointsCollection = "stats.checkpoints"
+
+// BucketCheckpoint records that a single (kind, requester, hour) bucket
+// of historical stats has already been committed for a project, so a
+// restarted sync can skip straight past it instead of reprocessing the
+// whole sync window from scratch. kind distinguishes the hourly test
+// stats bucket from the daily test/task rollups that are derived from
+// it (e.g. "hourly-test", "daily-task").
+type BucketCheckpoint struct {
+	Id          string    `bson:"_id" json:"id"`
+	ProjectId   string    `bson:"project_id" json:"project_id"`
+	Kind        string    `bson:"kind" json:"kind"`
+	Requester   string    `bson:"requester" json:"requester"`
+	Hour        time.Time `bson:"hour" json:"hour"`
+	CommittedAt time.Time `bson:"committed_at" json:"committed_at"`
+}
+
+func bucketCheckpointId(projectId, kind, requester string, hour time.Time) string {
+	return projectId + "." + kind + "." + requester + "." + hour.UTC().Format(time.RFC3339)
+}
+
+// IsBucketCommitted reports whether the (kind, requester, hour) bucket
+// has already been committed for projectId, so it can be skipped on
+// resume.
+func IsBucketCommitted(projectId, kind, requester string, hour time.Time) (bool, error) {
+	checkpoint := &BucketCheckpoint{}
+	err := db.FindOneQ(checkpointsCollection,
+		bson.M{"_id": bucketCheckpointId(projectId, kind, requester, hour)}, checkpoint)
+	if err == mgo.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "problem finding bucket checkpoint")
+	}
+
+	return true, nil
+}
+
+// CommitBucket records that the (kind, requester, hour) bucket has been
+// successfully synced for projectId. Committing is idempotent: calling
+// it again for the same bucket just overwrites the committed_at time.
+func CommitBucket(projectId, kind, requester string, hour time.Time, committedAt time.Time) error {
+	_, err := db.Upsert(checkpointsCollection,
+		bson.M{"_id": bucketCheckpointId(projectId, kind, requester, hour)},
+		bson.M{"$set": bson.M{
+			"project_id":   projectId,
+			"kind":         kind,
+			"requester":    requester,
+			"hour":         hour,
+			"committed_at": committedAt,
+		}})
+
+	return errors.Wrap(err, "problem committing bucket checkpoint")
+}
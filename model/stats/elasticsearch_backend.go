@@ -0,0 +1,151 @@
+package stats
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	elastic "gopkg.in/olivere/elastic.v5"
+)
+
+// indexDateFormat matches the evg-teststats-YYYY.MM.DD convention used
+// for daily indices, mirroring the layout compliance/report ingest
+// systems use for their own date-partitioned rollups.
+const indexDateFormat = "2006.01.02"
+
+// testStatsDocType is the ES5 mapping _type every test stats document is
+// indexed under. ES5 requires one on every bulk index action; since this
+// backend only ever writes one document shape per index, a single fixed
+// type is enough (the kind field is what actually distinguishes
+// hourly-test/daily-test/daily-task documents from each other).
+const testStatsDocType = "_doc"
+
+// Document kinds, mirroring the bucket kinds units/cache_historical_test_data.go
+// checkpoints against, so hourly-test/daily-test/daily-task documents
+// stay distinguishable once they're all written into the same
+// date-partitioned index.
+const (
+	hourlyTestStatsKind = "hourly-test"
+	dailyTestStatsKind  = "daily-test"
+	dailyTaskStatsKind  = "daily-task"
+)
+
+// ElasticsearchBackend writes hourly/daily test stats documents into
+// date-partitioned daily indices (one per UTC day) instead of Mongo
+// collections. Daily rollup documents (daily-test and daily-task) are
+// written with daily_latest set and hourly ones without it, so window
+// queries can filter on that flag plus an end_time range instead of
+// scanning every hourly document ever written for a task.
+type ElasticsearchBackend struct {
+	Client      *elastic.Client
+	IndexPrefix string
+}
+
+// NewElasticsearchBackend builds a backend that talks to client and
+// writes into indices named "<indexPrefix>-YYYY.MM.DD".
+func NewElasticsearchBackend(client *elastic.Client, indexPrefix string) *ElasticsearchBackend {
+	return &ElasticsearchBackend{Client: client, IndexPrefix: indexPrefix}
+}
+
+func (b *ElasticsearchBackend) indexName(day time.Time) string {
+	return fmt.Sprintf("%s-%s", b.IndexPrefix, day.UTC().Format(indexDateFormat))
+}
+
+type testStatsDoc struct {
+	ProjectId     string    `json:"project_id"`
+	Requester     string    `json:"requester"`
+	TaskName      string    `json:"task_name"`
+	Kind          string    `json:"kind"`
+	EndTime       time.Time `json:"end_time"`
+	JobTime       time.Time `json:"job_time"`
+	DailyLatest   bool      `json:"daily_latest"`
+	NumPass       int       `json:"num_pass"`
+	NumFail       int       `json:"num_fail"`
+	AvgDurationMs float64   `json:"avg_duration_ms"`
+}
+
+// testStatsDocId derives a stable, deterministic document id from the
+// fields that identify a bucket's per-task stat, so retrying a bucket
+// (per the checkpoint/resume design in checkpoint.go) overwrites the
+// same documents on the next attempt instead of duplicating them after
+// a partial bulk failure.
+func testStatsDocId(projectId, requester, kind, task string, period time.Time) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%s", projectId, requester, kind, task, period.UTC().Format(time.RFC3339))
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+func (b *ElasticsearchBackend) bulkIndexTasks(ctx context.Context, projectId, requester, kind string, period time.Time, tasks []string, jobTime time.Time, dailyLatest bool) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	periodStart := period
+	periodEnd := period.Add(time.Hour)
+	if kind != hourlyTestStatsKind {
+		periodEnd = period.AddDate(0, 0, 1)
+	}
+
+	summaries, err := FindTestResultSummaries(projectId, requester, tasks, periodStart, periodEnd)
+	if err != nil {
+		return errors.Wrap(err, "problem loading test result summaries")
+	}
+
+	index := b.indexName(period)
+	bulk := b.Client.Bulk().Index(index).Type(testStatsDocType)
+	for _, task := range tasks {
+		summary := summaries[task]
+		doc := testStatsDoc{
+			ProjectId:     projectId,
+			Requester:     requester,
+			TaskName:      task,
+			Kind:          kind,
+			EndTime:       period,
+			JobTime:       jobTime,
+			DailyLatest:   dailyLatest,
+			NumPass:       summary.NumPass,
+			NumFail:       summary.NumFail,
+			AvgDurationMs: summary.AvgDurationMs,
+		}
+		docId := testStatsDocId(projectId, requester, kind, task, period)
+		bulk = bulk.Add(elastic.NewBulkIndexRequest().Id(docId).Doc(doc))
+	}
+
+	resp, err := bulk.Do(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "problem bulk indexing test stats into %s", index)
+	}
+	if resp.Errors {
+		return errors.Errorf("problem bulk indexing test stats into %s: one or more documents failed", index)
+	}
+
+	return nil
+}
+
+func (b *ElasticsearchBackend) GenerateHourlyTestStats(projectId string, requester string, hour time.Time, tasks []string, jobTime time.Time) error {
+	return b.bulkIndexTasks(context.Background(), projectId, requester, hourlyTestStatsKind, hour, tasks, jobTime, false)
+}
+
+func (b *ElasticsearchBackend) GenerateDailyTestStatsFromHourly(projectId string, requester string, day time.Time, tasks []string, jobTime time.Time) error {
+	return b.bulkIndexTasks(context.Background(), projectId, requester, dailyTestStatsKind, day, tasks, jobTime, true)
+}
+
+func (b *ElasticsearchBackend) GenerateDailyTaskStats(projectId string, requester string, day time.Time, tasks []string, jobTime time.Time) error {
+	return b.bulkIndexTasks(context.Background(), projectId, requester, dailyTaskStatsKind, day, tasks, jobTime, true)
+}
+
+// LatestResultsInWindow returns every daily_latest=true document for
+// projectId whose end_time is within [start, end), searching all of the
+// backend's daily indices. It does not reduce the hits to one document
+// per task: a task with daily documents on several days in the window
+// appears once per day and kind.
+func (b *ElasticsearchBackend) LatestResultsInWindow(ctx context.Context, projectId string, start, end time.Time) (*elastic.SearchResult, error) {
+	query := elastic.NewBoolQuery().
+		Must(elastic.NewTermQuery("project_id", projectId)).
+		Must(elastic.NewTermQuery("daily_latest", true)).
+		Must(elastic.NewRangeQuery("end_time").Gte(start).Lt(end))
+
+	return b.Client.Search(fmt.Sprintf("%s-*", b.IndexPrefix)).Query(query).Do(ctx)
+}
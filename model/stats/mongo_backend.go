@@ -0,0 +1,21 @@
+package stats
+
+import "time"
+
+// MongoBackend is the original StatsBackend implementation: it delegates
+// to the package-level Generate* functions that read/write the
+// application's own hourly_test_stats/daily_test_stats/daily_task_stats
+// collections.
+type MongoBackend struct{}
+
+func (b *MongoBackend) GenerateHourlyTestStats(projectId string, requester string, hour time.Time, tasks []string, jobTime time.Time) error {
+	return GenerateHourlyTestStats(projectId, requester, hour, tasks, jobTime)
+}
+
+func (b *MongoBackend) GenerateDailyTestStatsFromHourly(projectId string, requester string, day time.Time, tasks []string, jobTime time.Time) error {
+	return GenerateDailyTestStatsFromHourly(projectId, requester, day, tasks, jobTime)
+}
+
+func (b *MongoBackend) GenerateDailyTaskStats(projectId string, requester string, day time.Time, tasks []string, jobTime time.Time) error {
+	return GenerateDailyTaskStats(projectId, requester, day, tasks, jobTime)
+}
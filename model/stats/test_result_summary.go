@@ -0,0 +1,63 @@
+package stats
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const testResultsCollection = "testresult"
+
+// TestResultSummary is the aggregated pass/fail/duration count for one
+// task's test results within a stats bucket. It's the same shape of
+// data the hourly/daily Mongo collections are built from; StatsBackend
+// implementations that don't write directly into those collections
+// (e.g. ElasticsearchBackend) still need it to populate their own
+// documents with real values instead of just recording that a task
+// name was seen.
+type TestResultSummary struct {
+	TaskName      string  `bson:"task_name"`
+	NumPass       int     `bson:"num_pass"`
+	NumFail       int     `bson:"num_fail"`
+	AvgDurationMs float64 `bson:"avg_duration_ms"`
+}
+
+// FindTestResultSummaries aggregates pass/fail counts and average
+// duration per task, for the given project/requester/tasks, over all
+// test results whose end time falls in [periodStart, periodEnd).
+func FindTestResultSummaries(projectId string, requester string, tasks []string, periodStart, periodEnd time.Time) (map[string]TestResultSummary, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{
+			"project_id": projectId,
+			"requester":  requester,
+			"task_name":  bson.M{"$in": tasks},
+			"end_time":   bson.M{"$gte": periodStart, "$lt": periodEnd},
+		}},
+		{"$group": bson.M{
+			"_id":             "$task_name",
+			"num_pass":        bson.M{"$sum": bson.M{"$cond": []interface{}{bson.M{"$eq": []interface{}{"$status", "pass"}}, 1, 0}}},
+			"num_fail":        bson.M{"$sum": bson.M{"$cond": []interface{}{bson.M{"$eq": []interface{}{"$status", "pass"}}, 0, 1}}},
+			"avg_duration_ms": bson.M{"$avg": "$duration_ms"},
+		}},
+		{"$project": bson.M{
+			"task_name":       "$_id",
+			"num_pass":        1,
+			"num_fail":        1,
+			"avg_duration_ms": 1,
+		}},
+	}
+
+	var rows []TestResultSummary
+	if err := db.Aggregate(testResultsCollection, pipeline, &rows); err != nil {
+		return nil, errors.Wrap(err, "problem aggregating test result summaries")
+	}
+
+	summaries := make(map[string]TestResultSummary, len(rows))
+	for _, row := range rows {
+		summaries[row.TaskName] = row
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/pkg/errors"
+)
+
+const versionCollection = "versions"
+
+// TriggeredVersion is the minimal version document a periodic trigger
+// creates: it's restricted to the build variants the trigger matched,
+// rather than the full set of variants a commit-triggered version would
+// activate.
+type TriggeredVersion struct {
+	Id            string    `bson:"_id" json:"id"`
+	ProjectId     string    `bson:"project_id" json:"project_id"`
+	Branch        string    `bson:"branch" json:"branch"`
+	Requester     string    `bson:"requester" json:"requester"`
+	Trigger       string    `bson:"trigger" json:"trigger"`
+	BuildVariants []string  `bson:"build_variants" json:"build_variants"`
+	CreateTime    time.Time `bson:"create_time" json:"create_time"`
+}
+
+// CreateVersionForTrigger creates a version for ref restricted to
+// variants, recording which periodic trigger caused it.
+func CreateVersionForTrigger(ctx context.Context, ref ProjectRef, trigger string, variants []string) error {
+	version := &TriggeredVersion{
+		Id:            ref.Identifier + "_" + trigger + "_" + time.Now().UTC().Format(time.RFC3339),
+		ProjectId:     ref.Identifier,
+		Branch:        ref.Branch,
+		Requester:     "trigger",
+		Trigger:       trigger,
+		BuildVariants: variants,
+		CreateTime:    time.Now(),
+	}
+
+	return errors.Wrap(db.Insert(versionCollection, version), "problem creating triggered version")
+}
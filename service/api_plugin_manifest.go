@@ -7,16 +7,16 @@ import (
 
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/manifest"
-	"github.com/evergreen-ci/evergreen/thirdparty"
 	"github.com/evergreen-ci/gimlet"
-	"github.com/google/go-github/github"
 	"github.com/pkg/errors"
 )
 
 // manifestLoadHandler attempts to get the manifest, if it exists it updates the expansions and returns
-// If it does not exist it performs GitHub API calls for each of the project's modules and gets
-// the head revision of the branch and inserts it into the manifest collection.
+// If it does not exist it resolves the head revision of each of the project's modules, using
+// whichever resolver matches the module's SCM provider, and inserts it into the manifest collection.
 // If there is a duplicate key error, then do a find on the manifest again.
+// With ?dry_run=1, it still resolves every module but never calls TryInsert, returning a JSON
+// diff of what would change versus any existing manifest instead of the manifest itself.
 func (as *APIServer) manifestLoadHandler(w http.ResponseWriter, r *http.Request) {
 	task := MustHaveTask(r)
 
@@ -46,7 +46,9 @@ func (as *APIServer) manifestLoadHandler(w http.ResponseWriter, r *http.Request)
 			errors.Wrapf(err, "error retrieving manifest with version id %s", task.Version))
 		return
 	}
-	if currentManifest != nil {
+
+	dryRun := r.URL.Query().Get("dry_run") == "1"
+	if currentManifest != nil && !dryRun {
 		gimlet.WriteJSON(w, currentManifest)
 		return
 	}
@@ -57,6 +59,32 @@ func (as *APIServer) manifestLoadHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	githubToken, err := as.Settings.GetGithubOauthToken()
+	if err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError, errors.Wrap(err, "error getting github token"))
+		return
+	}
+	resolverSettings := manifest.ResolverSettings{
+		GithubToken:    githubToken,
+		GitlabToken:    as.Settings.Credentials.Gitlab,
+		BitbucketToken: as.Settings.Credentials.Bitbucket,
+	}
+
+	if dryRun {
+		result, err := manifest.BuildDryRun(ctx, resolverSettings, task.Version, project, currentManifest)
+		if err != nil {
+			as.LoggedError(w, r, http.StatusInternalServerError,
+				errors.Wrap(err, "problem building manifest dry run"))
+			return
+		}
+
+		gimlet.WriteJSON(w, result)
+		return
+	}
+
 	// attempt to insert a manifest after making GitHub API calls
 	newManifest := &manifest.Manifest{
 		Id:          task.Version,
@@ -65,34 +93,25 @@ func (as *APIServer) manifestLoadHandler(w http.ResponseWriter, r *http.Request)
 		Branch:      projectRef.Branch,
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
-	defer cancel()
-
-	// populate modules
-	var gitBranch *github.Branch
+	// populate modules, dispatching each to the resolver for whichever
+	// SCM it's hosted on.
 	modules := make(map[string]*manifest.Module)
 	for _, module := range project.Modules {
-		var token string
-		owner, repo := module.GetRepoOwnerAndName()
-		token, err = as.Settings.GetGithubOauthToken()
+		resolver, err := manifest.GetResolver(module.Provider)
 		if err != nil {
-			as.LoggedError(w, r, http.StatusInternalServerError, errors.Wrap(err, "error getting github token"))
+			as.LoggedError(w, r, http.StatusInternalServerError,
+				errors.Wrapf(err, "problem finding manifest resolver for module %s", module.Name))
 			return
 		}
-		gitBranch, err = thirdparty.GetBranchEvent(ctx, token, owner, repo, module.Branch)
+
+		manifestModule, err := resolver.Resolve(ctx, resolverSettings, module)
 		if err != nil {
 			as.LoggedError(w, r, http.StatusInternalServerError,
-				errors.Wrapf(err, "problem retrieving getting git branch for module %s", module.Name))
+				errors.Wrapf(err, "problem resolving manifest for module %s", module.Name))
 			return
 		}
 
-		modules[module.Name] = &manifest.Module{
-			Branch:   module.Branch,
-			Revision: *gitBranch.Commit.SHA,
-			Repo:     repo,
-			Owner:    owner,
-			URL:      *gitBranch.Commit.URL,
-		}
+		modules[module.Name] = manifestModule
 	}
 	newManifest.Modules = modules
 	duplicate, err := newManifest.TryInsert()
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/evergreen-ci/evergreen/jobs"
+	"github.com/evergreen-ci/evergreen/jobstatus"
+	"github.com/evergreen-ci/gimlet"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+// jobStatusListHandler handles GET /rest/v2/jobs/<job_type>, returning
+// every persisted JobStatus for that job type.
+func (as *APIServer) jobStatusListHandler(w http.ResponseWriter, r *http.Request) {
+	jobType := mux.Vars(r)["job_type"]
+
+	statuses, err := jobstatus.FindJobStatusesByType(jobType)
+	if err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError,
+			errors.Wrapf(err, "problem finding job statuses for type %s", jobType))
+		return
+	}
+
+	gimlet.WriteJSON(w, statuses)
+}
+
+// jobCancelHandler handles POST /rest/v2/jobs/<job_type>/<id>/cancel. It
+// only marks the JobStatus as canceled; a running job's Run() polls
+// jobstatus.IsCanceled(j.ID()) between units of work and stops itself
+// once it sees the flag, the same way it polls ctx between units of
+// work to respect queue shutdown.
+
+func (as *APIServer) jobCancelHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	status, err := jobstatus.FindJobStatus(id)
+	if err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError,
+			errors.Wrapf(err, "problem finding job status for %s", id))
+		return
+	}
+	if status == nil {
+		as.LoggedError(w, r, http.StatusNotFound, errors.Errorf("job %s not found", id))
+		return
+	}
+
+	status.MarkCanceled()
+	if err = status.Upsert(); err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError,
+			errors.Wrapf(err, "problem canceling job %s", id))
+		return
+	}
+
+	gimlet.WriteJSON(w, status)
+}
+
+// jobRetriggerHandler handles POST /rest/v2/jobs/<job_type>/<id>/retrigger.
+// Like jobCancelHandler, id here is the full persisted JobStatus.Id (the
+// composite id amboy assigned the original run), not the raw argument a
+// worker's NewJob takes. The handler looks up that status to recover
+// the RawID the original run was enqueued with, then replays it through
+// the scheduler so the new run gets its own, freshly-derived job id
+// instead of colliding with the one being retriggered.
+func (as *APIServer) jobRetriggerHandler(w http.ResponseWriter, r *http.Request) {
+	jobType := mux.Vars(r)["job_type"]
+	id := mux.Vars(r)["id"]
+
+	existing, err := jobstatus.FindJobStatus(id)
+	if err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError,
+			errors.Wrapf(err, "problem finding job status for %s", id))
+		return
+	}
+	if existing == nil {
+		as.LoggedError(w, r, http.StatusNotFound, errors.Errorf("job %s not found", id))
+		return
+	}
+
+	status, err := jobs.DefaultScheduler.Enqueue(r.Context(), as.queue, jobType, existing.RawID)
+	if err != nil {
+		as.LoggedError(w, r, http.StatusBadRequest,
+			errors.Wrapf(err, "problem retriggering job %s/%s", jobType, id))
+		return
+	}
+
+	gimlet.WriteJSON(w, status)
+}
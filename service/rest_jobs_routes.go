@@ -0,0 +1,13 @@
+package service
+
+import "github.com/gorilla/mux"
+
+// AttachJobRoutes registers the /rest/v2/jobs endpoints on r: listing,
+// canceling, and manually re-triggering jobs managed by the jobs
+// subsystem. The full application's route table calls this alongside
+// the other AttachXRoutes methods when it builds its /rest/v2 router.
+func (as *APIServer) AttachJobRoutes(r *mux.Router) {
+	r.HandleFunc("/rest/v2/jobs/{job_type}", as.jobStatusListHandler).Methods("GET")
+	r.HandleFunc("/rest/v2/jobs/{job_type}/{id}/cancel", as.jobCancelHandler).Methods("POST")
+	r.HandleFunc("/rest/v2/jobs/{job_type}/{id}/retrigger", as.jobRetriggerHandler).Methods("POST")
+}
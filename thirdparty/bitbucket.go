@@ -0,0 +1,43 @@
+package thirdparty
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+const bitbucketAPIBase = "https://api.bitbucket.org/2.0"
+
+// GetBitbucketBranchHead fetches the head commit of a branch on
+// Bitbucket. token, if non-empty, is sent as an Authorization header so
+// private repositories can be resolved, not just public ones.
+func GetBitbucketBranchHead(ctx context.Context, owner, repo, branch, token string) (*BranchHead, error) {
+	url := fmt.Sprintf("%s/repositories/%s/%s/refs/branches/%s", bitbucketAPIBase, owner, repo, branch)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem building bitbucket request")
+	}
+	req = req.WithContext(ctx)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	var result struct {
+		Target struct {
+			Hash  string `json:"hash"`
+			Links struct {
+				HTML struct {
+					Href string `json:"href"`
+				} `json:"html"`
+			} `json:"links"`
+		} `json:"target"`
+	}
+	if err := doJSONRequest(req, &result); err != nil {
+		return nil, errors.Wrapf(err, "problem getting bitbucket branch %s/%s@%s", owner, repo, branch)
+	}
+
+	return &BranchHead{SHA: result.Target.Hash, URL: result.Target.Links.HTML.Href}, nil
+}
@@ -0,0 +1,30 @@
+package thirdparty
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// GetGenericGitBranchHead shells out to `git ls-remote` to find the head
+// revision of branch on repoURL. This is the fallback used for modules
+// hosted on an SCM that doesn't have a dedicated API integration.
+func GetGenericGitBranchHead(ctx context.Context, repoURL, branch string) (string, error) {
+	// "--" stops git from parsing repoURL as an option: module repo URLs
+	// come from project config, so a value like "--upload-pack=..."
+	// would otherwise be interpreted as a flag instead of a repository.
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", "--", repoURL, "refs/heads/"+branch)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "problem running git ls-remote against %s", repoURL)
+	}
+
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return "", errors.Errorf("branch %s not found in %s", branch, repoURL)
+	}
+
+	return fields[0], nil
+}
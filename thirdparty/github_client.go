@@ -0,0 +1,19 @@
+package thirdparty
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+// oauthHTTPClient returns an http.Client that authenticates as token
+// against GitHub, or http.DefaultClient if no token is set.
+func oauthHTTPClient(ctx context.Context, token string) *http.Client {
+	if token == "" {
+		return http.DefaultClient
+	}
+
+	tokenSource := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+	return oauth2.NewClient(ctx, tokenSource)
+}
@@ -0,0 +1,40 @@
+package thirdparty
+
+import (
+	"context"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// CommitMetadata is the subset of a commit's metadata dry-run diffs
+// report alongside the revision that would be written.
+type CommitMetadata struct {
+	Author string
+	Date   string
+}
+
+// GetCommitMetadata fetches author/date for a single commit SHA on
+// GitHub, for use in dry-run manifest diffs.
+func GetCommitMetadata(ctx context.Context, token, owner, repo, sha string) (*CommitMetadata, error) {
+	client := githubClient(ctx, token)
+
+	commit, _, err := client.Repositories.GetCommit(ctx, owner, repo, sha)
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem getting commit %s for %s/%s", sha, owner, repo)
+	}
+	if commit == nil || commit.Commit == nil || commit.Commit.Author == nil {
+		return nil, errors.Errorf("commit %s for %s/%s has no author metadata", sha, owner, repo)
+	}
+
+	return &CommitMetadata{
+		Author: commit.Commit.Author.GetName(),
+		Date:   commit.Commit.Author.GetDate().String(),
+	}, nil
+}
+
+// githubClient builds an authenticated go-github client the same way
+// the rest of thirdparty's GitHub calls do.
+func githubClient(ctx context.Context, token string) *github.Client {
+	return github.NewClient(oauthHTTPClient(ctx, token))
+}
@@ -0,0 +1,47 @@
+package thirdparty
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// BranchHead is the minimal commit information we need out of any SCM's
+// branch lookup, regardless of which API produced it.
+type BranchHead struct {
+	SHA string
+	URL string
+}
+
+const gitlabAPIBase = "https://gitlab.com/api/v4"
+
+// GetGitlabBranchHead fetches the head commit of a branch on GitLab using
+// the project's "owner/repo" path as GitLab's path-encoded project id.
+// token, if non-empty, is sent as an Authorization header so private
+// projects can be resolved, not just public ones.
+func GetGitlabBranchHead(ctx context.Context, owner, repo, branch, token string) (*BranchHead, error) {
+	url := fmt.Sprintf("%s/projects/%s%%2F%s/repository/branches/%s", gitlabAPIBase, owner, repo, branch)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem building gitlab request")
+	}
+	req = req.WithContext(ctx)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	var result struct {
+		Commit struct {
+			ID     string `json:"id"`
+			WebURL string `json:"web_url"`
+		} `json:"commit"`
+	}
+	if err := doJSONRequest(req, &result); err != nil {
+		return nil, errors.Wrapf(err, "problem getting gitlab branch %s/%s@%s", owner, repo, branch)
+	}
+
+	return &BranchHead{SHA: result.Commit.ID, URL: result.Commit.WebURL}, nil
+}
@@ -0,0 +1,23 @@
+package thirdparty
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// doJSONRequest issues req and unmarshals a JSON response body into out.
+func doJSONRequest(req *http.Request, out interface{}) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "problem making request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status code %d from %s", resp.StatusCode, req.URL)
+	}
+
+	return errors.Wrap(json.NewDecoder(resp.Body).Decode(out), "problem decoding response")
+}
@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/evergreen-ci/evergreen/jobstatus"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/stats"
 	"github.com/mongodb/amboy"
@@ -18,9 +21,23 @@ import (
 	"github.com/pkg/errors"
 )
 
+// errJobCanceled marks a run that stopped early because it observed its
+// own JobStatus flagged canceled (see jobCancelHandler), as opposed to
+// stopping because of a real bucket failure.
+var errJobCanceled = errors.New("job canceled")
+
 const (
 	cacheHistoricalTestDataName = "cache-historical-test-data"
 	maxSyncDuration             = time.Hour * 24 * 7 // one week
+
+	hourlyTestBucketKind = "hourly-test"
+	dailyTestBucketKind  = "daily-test"
+	dailyTaskBucketKind  = "daily-task"
+
+	// defaultBucketWorkers bounds how many hourly/daily buckets this job
+	// processes concurrently. Kept modest since each bucket does its own
+	// aggregation queries against the same database.
+	defaultBucketWorkers = 4
 )
 
 func init() {
@@ -30,7 +47,17 @@ func init() {
 
 type cacheHistoricalTestDataJob struct {
 	ProjectId string `bson:"project_id" json:"project_id" yaml:"project_id"`
-	job.Base  `bson:"job_base" json:"job_base" yaml:"job_base"`
+	// Workers bounds how many buckets are processed concurrently.
+	// Defaults to defaultBucketWorkers when unset.
+	Workers int `bson:"workers" json:"workers" yaml:"workers"`
+	// Backend selects which stats.StatsBackend stores the generated
+	// stats. Defaults to stats.MongoBackendType when unset.
+	Backend string `bson:"backend" json:"backend" yaml:"backend"`
+	// DryRun mirrors MigrationCommand.DryRun: when true, the job logs
+	// the buckets and task lists it would process, and skips both
+	// writing stats and committing bucket checkpoints.
+	DryRun   bool `bson:"dry_run" json:"dry_run" yaml:"dry_run"`
+	job.Base `bson:"job_base" json:"job_base" yaml:"job_base"`
 }
 
 type dailyStatsRollup map[time.Time]map[string][]string
@@ -48,6 +75,17 @@ func NewCacheHistoricalTestDataJob(projectId string, id string) amboy.Job {
 	return j
 }
 
+// NewCacheHistoricalTestDataDryRunJob is identical to
+// NewCacheHistoricalTestDataJob, except the returned job only logs what
+// it would sync instead of writing stats or bucket checkpoints.
+func NewCacheHistoricalTestDataDryRunJob(projectId string, id string) amboy.Job {
+	j := makeCacheHistoricalTestDataJob()
+	j.ProjectId = projectId
+	j.DryRun = true
+	j.SetID(fmt.Sprintf("%s.%s.%s", cacheHistoricalTestDataName, projectId, id))
+	return j
+}
+
 func makeCacheHistoricalTestDataJob() *cacheHistoricalTestDataJob {
 	j := &cacheHistoricalTestDataJob{
 		Base: job.Base{
@@ -64,27 +102,50 @@ func makeCacheHistoricalTestDataJob() *cacheHistoricalTestDataJob {
 func (j *cacheHistoricalTestDataJob) Run(ctx context.Context) {
 	defer j.MarkComplete()
 
+	status, err := jobstatus.FindJobStatus(j.ID())
+	if err != nil {
+		grip.Warning(message.WrapError(err, message.Fields{
+			"job_id":  j.ID(),
+			"message": "problem loading job status, progress won't be reported",
+		}))
+	}
+	if status != nil {
+		defer reportFinalStatus(status, j)
+		status.MarkInProgress(0)
+		if err := status.Upsert(); err != nil {
+			grip.Warning(message.WrapError(err, message.Fields{
+				"job_id":  j.ID(),
+				"message": "problem recording in-progress job status",
+			}))
+		}
+	}
+
 	// Lookup last sync date for project
 	statsStatus, err := stats.GetStatsStatus(j.ProjectId)
 	if err != nil {
-		if err != nil {
-			j.AddError(errors.Wrap(err, "error retrieving last sync date"))
-			return
-		}
+		j.AddError(errors.Wrap(err, "error retrieving last sync date"))
+		return
 	}
 
 	tasksToIgnore, err := getTasksToIgnore(j.ProjectId)
 	if err != nil {
-		if err != nil {
-			j.AddError(errors.Wrap(err, "error retrieving project settings"))
-			return
-		}
+		j.AddError(errors.Wrap(err, "error retrieving project settings"))
+		return
+	}
+
+	workers := j.Workers
+	if workers <= 0 {
+		workers = defaultBucketWorkers
 	}
 
 	jobContext := cacheHistoricalJobContext{
+		JobID:         j.ID(),
 		ProjectId:     j.ProjectId,
 		JobTime:       time.Now(),
 		TasksToIgnore: tasksToIgnore,
+		Workers:       workers,
+		DryRun:        j.DryRun,
+		Status:        status,
 	}
 
 	syncFromTime := statsStatus.ProcessedTasksUntil
@@ -94,6 +155,8 @@ func (j *cacheHistoricalTestDataJob) Run(ctx context.Context) {
 		"job_id":    j.ID(),
 		"sync_from": syncFromTime,
 		"sync_to":   syncToTime,
+		"workers":   workers,
+		"dry_run":   j.DryRun,
 		"message":   "running sync",
 	})
 
@@ -103,22 +166,35 @@ func (j *cacheHistoricalTestDataJob) Run(ctx context.Context) {
 		return
 	}
 
+	backend, err := stats.GetBackend(j.Backend)
+	if err != nil {
+		j.AddError(errors.Wrap(err, "error finding stats backend"))
+		return
+	}
+
 	generateMap := generateFunctions{
 		HourlyFns: map[string]generateStatsFn{
-			"test": stats.GenerateHourlyTestStats,
+			"test": backend.GenerateHourlyTestStats,
 		},
 		DailyFns: map[string]generateStatsFn{
-			"test": stats.GenerateDailyTestStatsFromHourly,
-			"task": stats.GenerateDailyTaskStats,
+			"test": backend.GenerateDailyTestStatsFromHourly,
+			"task": backend.GenerateDailyTaskStats,
 		},
 	}
 
-	err = jobContext.updateHourlyAndDailyStats(statsToUpdate, generateMap)
+	err = jobContext.updateHourlyAndDailyStats(ctx, statsToUpdate, generateMap)
 	if err != nil {
+		// A bucket failure stops this run, but every bucket that
+		// committed successfully recorded its own checkpoint, so the
+		// next run resumes from there instead of redoing the window.
 		j.AddError(errors.Wrap(err, "error generating hourly test stats"))
 		return
 	}
 
+	if j.DryRun {
+		return
+	}
+
 	// update last sync
 	err = stats.UpdateStatsStatus(j.ProjectId, jobContext.JobTime, syncToTime)
 	if err != nil {
@@ -127,10 +203,45 @@ func (j *cacheHistoricalTestDataJob) Run(ctx context.Context) {
 	}
 }
 
+// reportFinalStatus records status as canceled, errored, or succeeded
+// based on j's accumulated errors, once Run is done with it. It's
+// always called via defer so every return path in Run (including an
+// early one triggered by cancellation) leaves status in its correct
+// terminal state.
+func reportFinalStatus(status *jobstatus.JobStatus, j amboy.Job) {
+	switch {
+	case errors.Cause(j.Error()) == errJobCanceled:
+		status.MarkCanceled()
+	case j.Error() != nil:
+		status.MarkError(j.Error())
+	default:
+		status.MarkSuccess()
+	}
+
+	if err := status.Upsert(); err != nil {
+		grip.Warning(message.WrapError(err, message.Fields{
+			"job_id":  j.ID(),
+			"message": "problem recording final job status",
+		}))
+	}
+}
+
 type cacheHistoricalJobContext struct {
+	JobID         string
 	ProjectId     string
 	JobTime       time.Time
 	TasksToIgnore []*regexp.Regexp
+	Workers       int
+	DryRun        bool
+
+	// Status is the job's persisted JobStatus, or nil if Run couldn't
+	// load one (e.g. the job wasn't started through the jobs
+	// subsystem). completed/totalBuckets back its progress percentage;
+	// statusMu serializes the concurrent bucket workers' updates to it.
+	Status       *jobstatus.JobStatus
+	statusMu     sync.Mutex
+	completed    int32
+	totalBuckets int32
 }
 
 func getTasksToIgnore(projectId string) ([]*regexp.Regexp, error) {
@@ -161,19 +272,30 @@ func createRegexpFromStrings(filePatterns []string) ([]*regexp.Regexp, error) {
 	return tasksToIgnore, nil
 }
 
-func (c *cacheHistoricalJobContext) updateHourlyAndDailyStats(statsToUpdate []stats.StatsToUpdate, generateFns generateFunctions) error {
+// updateHourlyAndDailyStats processes the hourly buckets for statsToUpdate
+// with a bounded worker pool, then rolls the same buckets up into daily
+// stats. Buckets already committed by a previous, interrupted run are
+// skipped; the first bucket that fails to commit stops the whole run so
+// the next attempt picks up from exactly where this one left off.
+func (c *cacheHistoricalJobContext) updateHourlyAndDailyStats(ctx context.Context, statsToUpdate []stats.StatsToUpdate, generateFns generateFunctions) error {
+	hourlyBuckets := hourlyBucketsFromStats(statsToUpdate)
+	dailyStats := buildDailyStatsRollup(statsToUpdate)
+	dailyBuckets := dailyBucketsFromRollup(dailyStats)
+
+	atomic.StoreInt32(&c.totalBuckets, int32(len(hourlyBuckets)*len(generateFns.HourlyFns)+len(dailyBuckets)*len(generateFns.DailyFns)))
+
 	for name, genFn := range generateFns.HourlyFns {
-		err := c.iteratorOverHourlyStats(statsToUpdate, genFn, name)
-		if err != nil {
+		if err := c.processBucketsInPool(ctx, hourlyBuckets, hourlyTestBucketKind, name, genFn); err != nil {
 			return err
 		}
 	}
 
-	dailyStats := buildDailyStatsRollup(statsToUpdate)
-
 	for name, genFn := range generateFns.DailyFns {
-		err := c.iteratorOverDailyStats(dailyStats, genFn, name)
-		if err != nil {
+		kind := dailyTestBucketKind
+		if name == "task" {
+			kind = dailyTaskBucketKind
+		}
+		if err := c.processBucketsInPool(ctx, dailyBuckets, kind, name, genFn); err != nil {
 			return err
 		}
 	}
@@ -181,46 +303,199 @@ func (c *cacheHistoricalJobContext) updateHourlyAndDailyStats(statsToUpdate []st
 	return nil
 }
 
-func (c *cacheHistoricalJobContext) iteratorOverDailyStats(dailyStats dailyStatsRollup, fn generateStatsFn, displayName string) error {
-	for day, stats := range dailyStats {
-		for requester, tasks := range stats {
-			taskList := filterIgnoredTasks(tasks, c.TasksToIgnore)
-			if len(taskList) > 0 {
-				err := errors.Wrap(fn(c.ProjectId, requester, day, taskList, c.JobTime), "Could not sync daily stats")
-				grip.Warning(message.WrapError(err, message.Fields{
-					"project_id":   c.ProjectId,
-					"sync_date":    day,
-					"job_time":     c.JobTime,
-					"display_name": displayName,
-				}))
+// bucket is a unit of work: one requester's tasks for one hourly or
+// daily time period.
+type bucket struct {
+	Period    time.Time
+	Requester string
+	Tasks     []string
+}
+
+func hourlyBucketsFromStats(statsToUpdate []stats.StatsToUpdate) []bucket {
+	buckets := make([]bucket, 0, len(statsToUpdate))
+	for _, stat := range statsToUpdate {
+		buckets = append(buckets, bucket{Period: stat.Hour, Requester: stat.Requester, Tasks: stat.Tasks})
+	}
+
+	return buckets
+}
+
+func dailyBucketsFromRollup(rollup dailyStatsRollup) []bucket {
+	var buckets []bucket
+	for day, byRequester := range rollup {
+		for requester, tasks := range byRequester {
+			buckets = append(buckets, bucket{Period: day, Requester: requester, Tasks: tasks})
+		}
+	}
+
+	return buckets
+}
+
+// processBucketsInPool runs fn over every bucket using up to c.Workers
+// goroutines at a time, skipping buckets already committed under kind
+// and committing a checkpoint for each bucket it successfully processes.
+// It emits a metric per bucket, reports progress against the job's
+// JobStatus, and returns the first error encountered, after letting any
+// already in-flight buckets finish (and commit). Workers keep draining
+// bucketCh without doing further work once any bucket has failed, ctx is
+// done, or the job's own JobStatus is flagged canceled, so the run can
+// wind down promptly instead of racing the producer to a deadlock.
+func (c *cacheHistoricalJobContext) processBucketsInPool(ctx context.Context, buckets []bucket, kind string, displayName string, fn generateStatsFn) error {
+	bucketCh := make(chan bucket)
+	errCh := make(chan error, len(buckets))
+
+	var wg sync.WaitGroup
+	var failed int32
+	for i := 0; i < c.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range bucketCh {
+				if atomic.LoadInt32(&failed) != 0 {
+					continue
+				}
+				if c.canceled(ctx) {
+					errCh <- errJobCanceled
+					continue
+				}
+				err := c.processBucket(b, kind, displayName, fn)
+				c.reportProgress()
 				if err != nil {
-					return err
+					atomic.StoreInt32(&failed, 1)
 				}
+				errCh <- err
 			}
+		}()
+	}
+
+	for _, b := range buckets {
+		bucketCh <- b
+	}
+	close(bucketCh)
+	wg.Wait()
+	close(errCh)
+
+	var firstErr error
+	for err := range errCh {
+		if err != nil && firstErr == nil {
+			firstErr = err
 		}
 	}
 
-	return nil
+	return firstErr
 }
 
-func (c *cacheHistoricalJobContext) iteratorOverHourlyStats(stats []stats.StatsToUpdate, fn generateStatsFn, displayName string) error {
-	for _, stat := range stats {
-		taskList := filterIgnoredTasks(stat.Tasks, c.TasksToIgnore)
-		if len(taskList) > 0 {
-			err := errors.Wrap(fn(stat.ProjectId, stat.Requester, stat.Hour, taskList, c.JobTime), "Could not sync hourly stats")
-			grip.Warning(message.WrapError(err, message.Fields{
-				"project_id":   stat.ProjectId,
-				"sync_date":    stat.Hour,
-				"job_time":     c.JobTime,
-				"display_name": displayName,
-			}))
-			if err != nil {
-				return err
-			}
-		}
+// canceled reports whether this run should stop starting new buckets,
+// either because ctx was canceled (the queue is shutting down) or
+// because the job's own JobStatus was flagged canceled through the
+// REST cancel endpoint.
+func (c *cacheHistoricalJobContext) canceled(ctx context.Context) bool {
+	return jobCanceled(ctx, c.JobID)
+}
+
+// jobCanceled reports whether jobID's persisted JobStatus has been
+// flagged canceled through the REST cancel endpoint, or ctx itself has
+// been canceled (e.g. the queue is shutting down). Shared by every
+// Worker's Run() so each one polls cancellation the same way.
+func jobCanceled(ctx context.Context, jobID string) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	if jobID == "" {
+		return false
 	}
 
-	return nil
+	isCanceled, err := jobstatus.IsCanceled(jobID)
+	if err != nil {
+		grip.Warning(message.WrapError(err, message.Fields{
+			"job_id":  jobID,
+			"message": "problem checking job cancellation status",
+		}))
+		return false
+	}
+
+	return isCanceled
+}
+
+// reportProgress records that one more bucket has been attempted
+// (successfully or not) and updates the job's JobStatus progress
+// percentage accordingly. It's a no-op if Run couldn't load a
+// JobStatus.
+func (c *cacheHistoricalJobContext) reportProgress() {
+	done := atomic.AddInt32(&c.completed, 1)
+	if c.Status == nil {
+		return
+	}
+
+	total := atomic.LoadInt32(&c.totalBuckets)
+	var pct float64
+	if total > 0 {
+		pct = float64(done) / float64(total) * 100
+	}
+
+	c.statusMu.Lock()
+	defer c.statusMu.Unlock()
+	c.Status.MarkInProgress(pct)
+	if err := c.Status.Upsert(); err != nil {
+		grip.Warning(message.WrapError(err, message.Fields{
+			"job_id":  c.JobID,
+			"message": "problem recording job progress",
+		}))
+	}
+}
+
+func (c *cacheHistoricalJobContext) processBucket(b bucket, kind string, displayName string, fn generateStatsFn) error {
+	taskList := filterIgnoredTasks(b.Tasks, c.TasksToIgnore)
+	if len(taskList) == 0 {
+		return nil
+	}
+
+	committed, err := stats.IsBucketCommitted(c.ProjectId, kind, b.Requester, b.Period)
+	if err != nil {
+		return errors.Wrap(err, "Could not check bucket checkpoint")
+	}
+	if committed {
+		grip.Debug(message.Fields{
+			"project_id": c.ProjectId,
+			"kind":       kind,
+			"requester":  b.Requester,
+			"period":     b.Period,
+			"message":    "skipping already-committed bucket",
+		})
+		return nil
+	}
+
+	if c.DryRun {
+		grip.Info(message.Fields{
+			"project_id": c.ProjectId,
+			"kind":       kind,
+			"requester":  b.Requester,
+			"period":     b.Period,
+			"num_tasks":  len(taskList),
+			"tasks":      taskList,
+			"message":    "dry run: would sync bucket",
+		})
+		return nil
+	}
+
+	startAt := time.Now()
+	err = errors.Wrap(fn(c.ProjectId, b.Requester, b.Period, taskList, c.JobTime), "Could not sync stats bucket")
+	duration := time.Since(startAt)
+	grip.Warning(message.WrapError(err, message.Fields{
+		"project_id":   c.ProjectId,
+		"kind":         kind,
+		"sync_date":    b.Period,
+		"requester":    b.Requester,
+		"job_time":     c.JobTime,
+		"display_name": displayName,
+		"duration_ms":  duration.Milliseconds(),
+		"num_tasks":    len(taskList),
+	}))
+	if err != nil {
+		return err
+	}
+
+	return errors.Wrap(stats.CommitBucket(c.ProjectId, kind, b.Requester, b.Period, time.Now()), "Could not commit bucket checkpoint")
 }
 
 // Certain tasks always generate unique names, so they will never have any history. Filter out
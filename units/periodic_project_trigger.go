@@ -0,0 +1,208 @@
+package units
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/jobstatus"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/mongodb/amboy"
+	"github.com/mongodb/amboy/dependency"
+	"github.com/mongodb/amboy/job"
+	"github.com/mongodb/amboy/registry"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/message"
+	"github.com/pkg/errors"
+)
+
+const periodicProjectTriggerName = "periodic-project-trigger"
+
+func init() {
+	registry.AddJobType(periodicProjectTriggerName,
+		func() amboy.Job { return makePeriodicProjectTriggerJob() })
+}
+
+// periodicProjectTriggerJob scans every tracked project ref's build variants for
+// declared periodic triggers (nightly/weekly/any-branch) and enqueues a
+// version for each variant whose trigger is due. It's meant to run on a
+// cron, similar to Skia's periodic task scheduler.
+type periodicProjectTriggerJob struct {
+	job.Base `bson:"job_base" json:"job_base" yaml:"job_base"`
+}
+
+func NewPeriodicProjectTriggerJob(id string) amboy.Job {
+	j := makePeriodicProjectTriggerJob()
+	j.SetID(fmt.Sprintf("%s.%s", periodicProjectTriggerName, id))
+	return j
+}
+
+func makePeriodicProjectTriggerJob() *periodicProjectTriggerJob {
+	j := &periodicProjectTriggerJob{
+		Base: job.Base{
+			JobType: amboy.JobType{
+				Name:    periodicProjectTriggerName,
+				Version: 0,
+			},
+		},
+	}
+	j.SetDependency(dependency.NewAlways())
+	return j
+}
+
+func (j *periodicProjectTriggerJob) Run(ctx context.Context) {
+	defer j.MarkComplete()
+
+	status, err := jobstatus.FindJobStatus(j.ID())
+	if err != nil {
+		grip.Warning(message.WrapError(err, message.Fields{
+			"job_id":  j.ID(),
+			"message": "problem loading job status, progress won't be reported",
+		}))
+	}
+	if status != nil {
+		defer reportFinalStatus(status, j)
+		status.MarkInProgress(0)
+		if err := status.Upsert(); err != nil {
+			grip.Warning(message.WrapError(err, message.Fields{
+				"job_id":  j.ID(),
+				"message": "problem recording in-progress job status",
+			}))
+		}
+	}
+
+	projectRefs, err := model.FindAllTrackedProjectRefs()
+	if err != nil {
+		j.AddError(errors.Wrap(err, "error finding project refs"))
+		return
+	}
+
+	now := time.Now()
+	for i, ref := range projectRefs {
+		if jobCanceled(ctx, j.ID()) {
+			j.AddError(errJobCanceled)
+			return
+		}
+
+		if err := j.runTriggersForProject(ctx, ref, now); err != nil {
+			j.AddError(errors.Wrapf(err, "error running periodic triggers for project %s", ref.Identifier))
+		}
+
+		if status != nil {
+			status.MarkInProgress(float64(i+1) / float64(len(projectRefs)) * 100)
+			if err := status.Upsert(); err != nil {
+				grip.Warning(message.WrapError(err, message.Fields{
+					"job_id":  j.ID(),
+					"message": "problem recording job progress",
+				}))
+			}
+		}
+	}
+}
+
+func (j *periodicProjectTriggerJob) runTriggersForProject(ctx context.Context, ref model.ProjectRef, now time.Time) error {
+	project, err := model.FindProject("", &ref)
+	if err != nil {
+		return errors.Wrap(err, "error finding project")
+	}
+	if project == nil {
+		return nil
+	}
+
+	due, err := dueVariantsByTrigger(ref, project, now)
+	if err != nil {
+		return errors.Wrap(err, "error computing due triggers")
+	}
+
+	for trigger, variants := range due {
+		if len(variants) == 0 {
+			continue
+		}
+
+		grip.Info(message.Fields{
+			"job_id":   j.ID(),
+			"project":  ref.Identifier,
+			"trigger":  trigger,
+			"variants": variants,
+			"message":  "enqueueing periodic trigger version",
+		})
+
+		if err := enqueuePeriodicTriggerVersion(ctx, ref, trigger, variants); err != nil {
+			return errors.Wrapf(err, "error enqueueing version for trigger %s", trigger)
+		}
+
+		if err := model.UpdatePeriodicTriggerStatus(ref.Identifier, trigger, now); err != nil {
+			return errors.Wrap(err, "error updating periodic trigger status")
+		}
+	}
+
+	return nil
+}
+
+// dueVariantsByTrigger returns, for each trigger type declared anywhere
+// in the project, the list of build variants that should run now. A
+// variant's effective trigger is resolved per task it runs (task
+// override > task's own trigger > variant's trigger, see
+// model.EffectiveTrigger), so a variant can appear under more than one
+// trigger type if its tasks disagree, and only needs one due trigger to
+// be included for that type. TriggerOnDemand is never included: it's
+// only available to force/try invocations, not this scheduler.
+// TriggerMasterOnly entries are dropped when the project ref's branch is
+// not a default branch name (see model.AppliesToBranch).
+func dueVariantsByTrigger(ref model.ProjectRef, project *model.Project, now time.Time) (map[string][]string, error) {
+	due := map[string][]string{}
+	seen := map[string]map[string]bool{} // trigger -> variant name -> already added
+
+	for _, variant := range project.BuildVariants {
+		triggers := map[string]bool{}
+		for _, bvTask := range variant.Tasks {
+			task := project.FindProjectTask(bvTask.Name)
+			trigger := model.EffectiveTrigger(task, bvTask, variant)
+			if trigger != "" {
+				triggers[trigger] = true
+			}
+		}
+
+		for trigger := range triggers {
+			if trigger == model.TriggerOnDemand {
+				continue
+			}
+
+			if !model.AppliesToBranch(trigger, ref.Branch) {
+				continue
+			}
+
+			status, err := model.FindPeriodicTriggerStatus(ref.Identifier, trigger)
+			if err != nil {
+				return nil, err
+			}
+
+			var lastRun time.Time
+			if status != nil {
+				lastRun = status.LastRun
+			}
+
+			if !model.IsDue(trigger, lastRun, now) {
+				continue
+			}
+
+			if seen[trigger] == nil {
+				seen[trigger] = map[string]bool{}
+			}
+			if seen[trigger][variant.Name] {
+				continue
+			}
+			seen[trigger][variant.Name] = true
+			due[trigger] = append(due[trigger], variant.Name)
+		}
+	}
+
+	return due, nil
+}
+
+// enqueuePeriodicTriggerVersion kicks off version creation for the given
+// variants. It's a thin wrapper so the scheduling logic above can be
+// tested independently of how versions actually get created.
+func enqueuePeriodicTriggerVersion(ctx context.Context, ref model.ProjectRef, trigger string, variants []string) error {
+	return model.CreateVersionForTrigger(ctx, ref, trigger, variants)
+}